@@ -0,0 +1,172 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+const (
+	minZoom = 0.5
+	maxZoom = 4.0
+
+	// panSpeed is how fast the arrow keys pan the camera, in screen pixels
+	// per second; Update divides it by Zoom before moving Pos, so the
+	// on-screen speed is the same at every zoom level.
+	panSpeed = 300.0
+
+	// zoomSpeed controls how much each wheel notch scales the zoom.
+	zoomSpeed = 1.2
+)
+
+// Camera controls what part of the world is visible: a pan position, a
+// zoom level, and the window viewport it's drawn into. Its matrix is
+// chained onto every sprite's own transform so the whole world pans and
+// zooms together.
+type Camera struct {
+	Pos      pixel.Vec
+	Zoom     float64
+	Viewport pixel.Rect
+
+	dragging  bool
+	lastMouse pixel.Vec
+}
+
+// NewCamera returns a camera centered on the origin at 1x zoom, sized to
+// viewport.
+func NewCamera(viewport pixel.Rect) *Camera {
+	return &Camera{Zoom: 1, Viewport: viewport}
+}
+
+// Matrix returns the transform that maps virtual screen space (as produced
+// by pointToScreenSpace) to the camera's current view: shift Pos to the
+// origin, scale by Zoom, then re-center on the viewport.
+func (c *Camera) Matrix() pixel.Matrix {
+	return pixel.IM.
+		Moved(c.Pos.Scaled(-1)).
+		ScaledXY(pixel.ZV, pixel.V(c.Zoom, c.Zoom)).
+		Moved(c.Viewport.Center())
+}
+
+// Unproject maps a point in window space (e.g. win.MousePosition()) back
+// into virtual screen space, undoing Matrix.
+func (c *Camera) Unproject(screen pixel.Vec) pixel.Vec {
+	return c.Matrix().Unproject(screen)
+}
+
+// Update pans the camera with the arrow keys or a middle-mouse drag, and
+// zooms it with the scroll wheel, clamped to [minZoom, maxZoom]. dt is the
+// frame time in seconds.
+func (c *Camera) Update(win *pixelgl.Window, dt float64) {
+	pan := pixel.ZV
+	if win.Pressed(pixelgl.KeyLeft) {
+		pan.X -= panSpeed * dt
+	}
+	if win.Pressed(pixelgl.KeyRight) {
+		pan.X += panSpeed * dt
+	}
+	if win.Pressed(pixelgl.KeyDown) {
+		pan.Y -= panSpeed * dt
+	}
+	if win.Pressed(pixelgl.KeyUp) {
+		pan.Y += panSpeed * dt
+	}
+	c.Pos = c.Pos.Add(pan.Scaled(1 / c.Zoom))
+
+	mouse := win.MousePosition()
+	switch {
+	case win.JustPressed(pixelgl.MouseButtonMiddle):
+		c.dragging = true
+		c.lastMouse = mouse
+	case win.JustReleased(pixelgl.MouseButtonMiddle):
+		c.dragging = false
+	case c.dragging:
+		c.Pos = c.Pos.Sub(mouse.Sub(c.lastMouse).Scaled(1 / c.Zoom))
+		c.lastMouse = mouse
+	}
+
+	if scroll := win.MouseScroll(); scroll.Y != 0 {
+		c.Zoom = clamp(c.Zoom*math.Pow(zoomSpeed, scroll.Y), minZoom, maxZoom)
+	}
+}
+
+// clamp restricts v to [lo, hi].
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// screenToCell is the inverse of pointToScreenSpace at z=0: given a point
+// in virtual screen space, it returns the cell it falls in.
+func screenToCell(s pixel.Vec) (x, y int) {
+	a := (s.X - origin.X*tileSize.X - tileSize.X/2) / (tileSize.X / 2)
+	b := (s.Y - origin.Y*tileSize.Y - tileSize.Y/2) / (tileSize.Y / 2)
+	// Cells are centered on integer coordinates, so round rather than floor.
+	return int(math.Floor((a+b)/2 + 0.5)), int(math.Floor((b-a)/2 + 0.5))
+}
+
+// clampInt restricts v to [lo, hi].
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// cullPad returns how many extra cells visibleCellRange should pad its
+// projected box by: one flat cell for ordinary diamond overlap, plus enough
+// to cover the tallest elevation offset pointToScreenSpace can apply. Each
+// z-level shifts a tile up the screen by tileH pixels, and screenToCell
+// turns a vertical screen offset into an equal change in both x and y (they
+// share b's coefficient), so a column at the top of a worldHeight-tall map
+// can land up to worldHeight*tileH/(tileSize.Y/2)/2 cells outside the box
+// the ground-level corners project to.
+func cullPad() int {
+	extra := math.Ceil(float64(worldHeight) * tileH / (tileSize.Y / 2) / 2)
+	return 1 + int(extra)
+}
+
+// visibleCellRange computes the inclusive cell range [minX,maxX]x[minY,maxY]
+// that could be visible through cam, by inverse-projecting its viewport's
+// four corners back to cell space. The render loop only walks this range
+// instead of the whole worldSizeX x worldSizeY grid, so worlds much larger
+// than the default 10x10 stay fast to draw.
+func visibleCellRange(cam *Camera) (minX, maxX, minY, maxY int) {
+	corners := [4]pixel.Vec{
+		cam.Viewport.Min,
+		pixel.V(cam.Viewport.Max.X, cam.Viewport.Min.Y),
+		cam.Viewport.Max,
+		pixel.V(cam.Viewport.Min.X, cam.Viewport.Max.Y),
+	}
+
+	minX, minY = worldSizeX, worldSizeY
+	maxX, maxY = 0, 0
+	for _, corner := range corners {
+		x, y := screenToCell(cam.Unproject(corner))
+		if x < minX {
+			minX = x
+		}
+		if x > maxX {
+			maxX = x
+		}
+		if y < minY {
+			minY = y
+		}
+		if y > maxY {
+			maxY = y
+		}
+	}
+
+	pad := cullPad()
+	return clampInt(minX-pad, 0, worldSizeX-1), clampInt(maxX+pad, 0, worldSizeX-1),
+		clampInt(minY-pad, 0, worldSizeY-1), clampInt(maxY+pad, 0, worldSizeY-1)
+}
@@ -0,0 +1,98 @@
+package main
+
+import "math/rand"
+
+// numClusters is how many grass/foliage clusters Generate scatters across
+// the map.
+const numClusters = 12
+
+// environment holds decoration tiles (currently just trees) drawn on top of
+// the ground layer. It's a separate worldSizeY x worldSizeX grid rather
+// than another world z-level, since decorations sit on the surface tile
+// they occupy rather than stacking a full elevation step above it.
+var environment = newLayer()
+
+// latticeHash deterministically hashes a (seed, x, y) lattice point, used
+// as a cheap stand-in for value noise when picking a biome per cell.
+func latticeHash(seed int64, x, y int) uint32 {
+	h := uint64(seed)
+	h = h*31 + uint64(x)
+	h = h*31 + uint64(y)
+	h ^= h >> 13
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return uint32(h)
+}
+
+// biomeAt picks a ground tile for (x, y) from the seed's lattice: mostly
+// grass, with patches of dirt and rarer stone.
+func biomeAt(seed int64, x, y int) tileType {
+	switch h := latticeHash(seed, x, y) % 100; {
+	case h < 60:
+		return grass
+	case h < 85:
+		return dirt
+	default:
+		return stone
+	}
+}
+
+// surfaceZ returns the z-level of the topmost non-blank tile at (x, y), or
+// 0 if the column is empty.
+func surfaceZ(x, y int) int {
+	for z := worldHeight - 1; z >= 0; z-- {
+		if world[z][y][x] != blank {
+			return z
+		}
+	}
+	return 0
+}
+
+// Generate procedurally fills the ground layer with a biome mix of grass,
+// dirt, and stone, then scatters tree clusters across the environment
+// layer on top: for a handful of random seed points, it splats a round,
+// variable-radius blob of grass and, inside that blob, scatters tiles of
+// alternating tree sprites so clusters don't read as visually uniform.
+func Generate(seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+
+	growWorld(1)
+	ground := newLayer()
+	for y := 0; y < worldSizeY; y++ {
+		for x := 0; x < worldSizeX; x++ {
+			ground[y][x] = biomeAt(seed, x, y)
+		}
+	}
+
+	env := newLayer()
+	treeVariant := 0
+	for c := 0; c < numClusters; c++ {
+		cx, cy := rng.Intn(worldSizeX), rng.Intn(worldSizeY)
+		radius := 2 + rng.Intn(7)
+
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				if dx*dx+dy*dy > radius*radius {
+					continue // keep the blob round, not square
+				}
+				x, y := cx+dx, cy+dy
+				if x < 0 || x >= worldSizeX || y < 0 || y >= worldSizeY {
+					continue
+				}
+
+				ground[y][x] = grass
+				if env[y][x] == blank && rng.Float64() < 0.15 {
+					if treeVariant == 0 {
+						env[y][x] = tree1
+					} else {
+						env[y][x] = tree2
+					}
+					treeVariant = 1 - treeVariant
+				}
+			}
+		}
+	}
+
+	world[0] = ground
+	environment = env
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Face identifies which visible face of a tile's cube a click landed on.
+type Face int
+
+const (
+	FaceNone Face = iota
+	FaceTop
+	FaceLeft
+	FaceRight
+)
+
+// faceCenter returns the screen-space center of the top face of the tile at
+// (x, y, z) — exactly where pointToScreenSpace draws its sprite.
+func faceCenter(x, y, z int) pixel.Vec {
+	return pointToScreenSpace(float64(x), float64(y), float64(z))
+}
+
+// hitTest scans z-levels from top to bottom, over the cells in
+// [minX,maxX]x[minY,maxY], and returns the coordinates and face of the
+// topmost tile under the mouse cursor. face is FaceNone, with zeroed
+// coordinates, when the cursor isn't over any tile in range.
+func hitTest(mouse pixel.Vec, minX, maxX, minY, maxY int) (x, y, z int, face Face) {
+	for z = worldHeight - 1; z >= 0; z-- {
+		for y = minY; y <= maxY; y++ {
+			for x = minX; x <= maxX; x++ {
+				if world[z][y][x] == blank {
+					continue
+				}
+
+				center := faceCenter(x, y, z)
+				dx := mouse.X - center.X
+				if math.Abs(dx) > tileSize.X/2 {
+					continue // Outside the column entirely
+				}
+
+				// Top face: the diamond centered exactly where the tile's
+				// sprite is drawn.
+				dyTop := mouse.Y - center.Y
+				if math.Abs(dx)/(tileSize.X/2)+math.Abs(dyTop)/(tileSize.Y/2) <= 1 {
+					return x, y, z, FaceTop
+				}
+
+				// Not the top: check the full-height diamond centered on
+				// the cube body (tileH/2 below the top face), and split it
+				// into a left/right wall by which side of centerX the
+				// mouse landed on.
+				dy := mouse.Y - (center.Y - tileH/2)
+				if math.Abs(dx)/(tileSize.X/2)+math.Abs(dy)/(tileSize.Y/2) <= 1 {
+					if mouse.X < center.X {
+						return x, y, z, FaceLeft
+					}
+					return x, y, z, FaceRight
+				}
+			}
+		}
+	}
+	return 0, 0, 0, FaceNone
+}
+
+// HitTest reports the cell and face (top, left, or right) of the topmost
+// tile under the mouse cursor, or FaceNone if no tile is hit. This replaces
+// the old cross-product picking hack and is what lets a click distinguish a
+// tile's top from the walls of the cube beneath it once elevation is in
+// play. It scans the whole world; callers that already have a camera should
+// call hitTest directly with its visible cell range instead.
+func HitTest(mouse pixel.Vec) (cell pixel.Vec, face Face) {
+	x, y, _, face := hitTest(mouse, 0, worldSizeX-1, 0, worldSizeY-1)
+	return pixel.V(float64(x), float64(y)), face
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestHitTestTopFace(t *testing.T) {
+	growWorld(2)
+	defer func() {
+		world = map[int][][]tileType{}
+		worldHeight = 1
+	}()
+
+	x, y, z := 3, 4, 1
+	world[z][y][x] = grass
+
+	mouse := pointToScreenSpace(float64(x), float64(y), float64(z))
+
+	gotX, gotY, gotZ, face := hitTest(mouse, 0, worldSizeX-1, 0, worldSizeY-1)
+	if face != FaceTop {
+		t.Fatalf("hitTest(%v) face = %v, want FaceTop", mouse, face)
+	}
+	if gotX != x || gotY != y || gotZ != z {
+		t.Fatalf("hitTest(%v) = (%d, %d, %d), want (%d, %d, %d)", mouse, gotX, gotY, gotZ, x, y, z)
+	}
+}
@@ -5,11 +5,11 @@ import (
 	"image"
 	_ "image/png"
 	"os"
+	"time"
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
-	"github.com/golang/geo/r2"
 	"golang.org/x/image/colornames"
 )
 
@@ -24,20 +24,93 @@ const (
 	stoneEdgeE tileType = iota
 	stoneEdgeS tileType = iota
 	stoneEdgeW tileType = iota
+	dirt       tileType = iota
+	tree1      tileType = iota
+	tree2      tileType = iota
+
+	// firstDynamicTile is the first tileType index handed out to tiles
+	// loaded from external tilesets (see LoadTMX); built-in tiles above
+	// always occupy the indices below it.
+	firstDynamicTile
 )
 
+// tileH is the pixel offset applied between consecutive z-levels so that
+// stacked tiles read as elevation rather than overlapping tops.
+const tileH = 15
+
+// windowWidth/windowHeight size the game window. They're fixed, independent
+// of worldSizeX/worldSizeY, so the Camera actually has something to pan and
+// zoom within — and visibleCellRange has something to cull — instead of the
+// window always being exactly as big as the loaded world.
 const (
-	worldSizeX = 10
-	worldSizeY = 10
+	windowWidth  = 1024
+	windowHeight = 768
 )
 
 var (
-	worldSize = pixel.V(worldSizeX, worldSizeY)
+	// worldSizeX/worldSizeY are the current world's cell dimensions. They
+	// default to a 10x10 grid but are resized by LoadTMX to match whatever
+	// map was loaded.
+	worldSizeX = 10
+	worldSizeY = 10
+
+	worldSize = pixel.V(float64(worldSizeX), float64(worldSizeY))
 	tileSize  = pixel.V(63, 32)
 	origin    = pixel.V(5, 1)
-	world     [worldSizeX][worldSizeY]tileType
+
+	// worldHeight is the number of z-levels currently populated in world.
+	// It is not a const because maps/buildings grow the world upward at
+	// runtime (e.g. via LoadTMX or terrain generation).
+	worldHeight = 1
+
+	// world is keyed by z-level so the world can gain height without a
+	// fixed array bound; each level is a worldSizeY x worldSizeX grid.
+	world = map[int][][]tileType{}
+
+	// tileSprites is the runtime sprite table, indexed by tileType. It
+	// starts out sized for the built-in tiles and grows as tilesets are
+	// registered (see registerTileset).
+	tileSprites = make([]*pixel.Sprite, firstDynamicTile)
+
+	// tileWalkable is the runtime walkability table, indexed by tileType.
+	// It starts out covering the built-in tiles — everything walkable
+	// except blank and stone — and grows alongside tileSprites as
+	// tilesets are registered, so TMX-loaded terrain can mark its own
+	// obstacles (via each TSX tile's "walkable" property) instead of
+	// being checked against the built-in enum.
+	tileWalkable = func() []bool {
+		w := make([]bool, firstDynamicTile)
+		for i := range w {
+			w[i] = true
+		}
+		w[blank] = false
+		w[stone] = false
+		return w
+	}()
 )
 
+// newLayer allocates a blank worldSizeY x worldSizeX grid of tiles.
+func newLayer() [][]tileType {
+	layer := make([][]tileType, worldSizeY)
+	for y := range layer {
+		layer[y] = make([]tileType, worldSizeX)
+	}
+	return layer
+}
+
+// growWorld ensures world has at least height z-levels, allocating any
+// missing layers as blank.
+func growWorld(height int) {
+	for z := 0; z < height; z++ {
+		if _, ok := world[z]; !ok {
+			world[z] = newLayer()
+		}
+	}
+	if height > worldHeight {
+		worldHeight = height
+	}
+}
+
 // loadPicture loads a picture from memory and returns a pixel picture.
 func loadPicture(path string) (pixel.Picture, error) {
 	file, err := os.Open(path)
@@ -52,26 +125,34 @@ func loadPicture(path string) (pixel.Picture, error) {
 	return pixel.PictureDataFromImage(img), nil
 }
 
-// pointToScreenSpace takes coordinates from the world space and maps them to
-// coordinates in the virtual screen space.
-func pointToScreenSpace(x, y float64) pixel.Vec {
+// pointToScreenSpace takes coordinates from the world space (including the
+// z-level) and maps them to coordinates in the virtual screen space. Each
+// z-level is offset upward by tileH so stacked tiles appear as elevation.
+func pointToScreenSpace(x, y, z float64) pixel.Vec {
 	return pixel.V(
 		(origin.X*tileSize.X+(x-y)*(tileSize.X/2))+tileSize.X/2,
-		(origin.Y*tileSize.Y+(x+y)*(tileSize.Y/2))+tileSize.Y/2,
+		(origin.Y*tileSize.Y+(x+y)*(tileSize.Y/2))+tileSize.Y/2+z*tileH,
 	)
 }
 
 // run is the main game function.
 func run() {
+	// Populate the world first, so that loading a TMX map (via a path on
+	// the command line) has already resized worldSizeX/worldSizeY before
+	// the path graph and camera culling below read them. The window itself
+	// stays windowWidth x windowHeight whatever map is loaded.
+	if len(os.Args) > 1 {
+		if err := LoadTMX(os.Args[1]); err != nil {
+			panic(err)
+		}
+	} else {
+		Generate(1)
+	}
+
 	// Create the window config
 	cfg := pixelgl.WindowConfig{
-		Title: "@xoreo isometric-engine",
-		Bounds: pixel.R(
-			0,
-			0,
-			(worldSizeX+2)*tileSize.X,
-			(worldSizeY)*tileSize.X,
-		),
+		Title:  "@xoreo isometric-engine",
+		Bounds: pixel.R(0, 0, windowWidth, windowHeight),
 	}
 
 	// Create the window itself
@@ -86,102 +167,97 @@ func run() {
 		panic(err)
 	}
 
-	var tileSprites [6]*pixel.Sprite
-
 	tileSprites[grass] = pixel.NewSprite(spriteSheet, pixel.R(257, 67, tileSize.X, tileSize.Y))
 	tileSprites[stone] = pixel.NewSprite(spriteSheet, pixel.R(1, 34, tileSize.X, tileSize.Y))
 	tileSprites[selected] = pixel.NewSprite(spriteSheet, pixel.R(
 		1, 1, tileSize.X, tileSize.Y,
 	))
+	tileSprites[dirt] = pixel.NewSprite(spriteSheet, pixel.R(257, 1, tileSize.X, tileSize.Y))
+	tileSprites[tree1] = pixel.NewSprite(spriteSheet, pixel.R(321, 1, tileSize.X, tileSize.Y*2))
+	tileSprites[tree2] = pixel.NewSprite(spriteSheet, pixel.R(385, 1, tileSize.X, tileSize.Y*2))
 
-	// Initialize the world map to blank tiles
-	for y, _ := range world {
-		for x, _ := range world[y] {
-			world[y][x] = grass
-		}
-	}
+	w := World{}
+	w.BuildPathGraph()
+
+	var (
+		pathFrom pixel.Vec
+		path     []pixel.Vec
+	)
+
+	cam := NewCamera(win.Bounds())
+	last := time.Now()
 
 	// Main loop
 	for !win.Closed() {
+		dt := time.Since(last).Seconds()
+		last = time.Now()
+		cam.Update(win, dt)
+		camMatrix := cam.Matrix()
+
 		// Clear the screen
 		win.Clear(colornames.White)
 
-		mouseVec := win.MousePosition() // Get the position of the mouse
-		boardSpaceCell := pixel.V(
-			float64(int(mouseVec.X)/int(tileSize.X)), // x position
-			float64(int(mouseVec.Y)/int(tileSize.Y)), // y position
-		)
-
-		// Map the cell coords in screen space to those in cell space
-		cellSpaceCell := pixel.V(
-			(boardSpaceCell.Y-origin.Y)+(boardSpaceCell.X-origin.X),
-			(boardSpaceCell.Y-origin.Y)-(boardSpaceCell.X-origin.X),
-		)
-
-		// Render all of the tiles, y first to add depth
-		for y := 0; y < worldSizeY; y++ {
-			for x := 0; x < worldSizeX; x++ {
-				// Map to screen space
-				screenVec := pointToScreenSpace(float64(x), float64(y))
-				switch world[x][y] {
-				case grass:
-					// Draw the grass tile sprite
-					tileSprites[grass].Draw(win, pixel.IM.Moved(screenVec))
-					break
+		mouseVec := cam.Unproject(win.MousePosition()) // Mouse, in virtual screen space
+
+		minX, maxX, minY, maxY := visibleCellRange(cam)
+
+		// Render all of the layers bottom-up, y first within each layer to
+		// add depth, culled to what the camera can actually see
+		for z := 0; z < worldHeight; z++ {
+			for y := minY; y <= maxY; y++ {
+				for x := minX; x <= maxX; x++ {
+					// Map to screen space
+					t := world[z][y][x]
+					if t == blank {
+						continue
+					}
+					if int(t) < len(tileSprites) && tileSprites[t] != nil {
+						screenVec := pointToScreenSpace(float64(x), float64(y), float64(z))
+						tileSprites[t].Draw(win, pixel.IM.Moved(screenVec).Chained(camMatrix))
+					}
 				}
 			}
 		}
 
+		// Render the environment layer (trees, etc.) after the ground tile
+		// at each column's surface so it sorts on top of it
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				t := environment[y][x]
+				if t == blank || int(t) >= len(tileSprites) || tileSprites[t] == nil {
+					continue
+				}
+				screenVec := pointToScreenSpace(float64(x), float64(y), float64(surfaceZ(x, y)))
+				tileSprites[t].Draw(win, pixel.IM.Moved(screenVec).Chained(camMatrix))
+			}
+		}
+
 		imd := imdraw.New(nil)           // Initialize the mesh
 		imd.Color = pixel.RGB(255, 0, 0) // Red
 
-		// Calculate where the point is in relation to the border of the tile
-		tx := tileSize.X
-		ty := tileSize.Y
-		P := r2.Point{mouseVec.X, mouseVec.Y}
-		O := r2.Point{boardSpaceCell.X * tx, boardSpaceCell.Y * ty}
-		A := r2.Point{
-			O.X + tx/2,
-			O.Y,
-		}
-		B := r2.Point{
-			O.X,
-			O.Y + ty/2,
-		}
-		C := r2.Point{
-			O.X + tx/2,
-			O.Y + ty,
-		}
-		D := r2.Point{
-			O.X + tx,
-			O.Y + ty/2,
-		}
+		// Highlight the topmost tile under the cursor, if any, and report
+		// which face of it was hit
+		if x, y, z, face := hitTest(mouseVec, minX, maxX, minY, maxY); face != FaceNone {
+			fmt.Printf("hit x: %d, y: %d, z: %d, face: %v\n", x, y, z, face)
+			tileSprites[selected].Draw(win, pixel.IM.Moved(
+				pointToScreenSpace(float64(x), float64(y), float64(z)),
+			).Chained(camMatrix)) // Draw the highlighted sprite on the cell
 
-		// Calculate the cross products
-		dAB := (P.X-A.X)*(B.Y-A.Y) - (P.Y-A.Y)*(B.X-A.X)
-		dBC := (P.X-B.X)*(C.Y-B.Y) - (P.Y-B.Y)*(C.X-B.X)
-		dCD := (P.X-C.X)*(D.Y-C.Y) - (P.Y-C.Y)*(D.X-C.X)
-		dDA := (P.X-D.X)*(A.Y-D.Y) - (P.Y-D.Y)*(A.X-D.X)
-		fmt.Printf("dAB: %f\ndBC: %f\ndCD: %f\ndDA: %f\n\n", dAB, dBC, dCD, dDA)
-
-		// Change the cellSpaceCell accordingly
-		if dAB < 0 { // Bottom left
-			cellSpaceCell.X -= 1
-		} else if dBC < 0 { // Top left
-			cellSpaceCell.Y += 1
-		} else if dCD < 0 { // Top right
-			cellSpaceCell.X += 1
-		} else if dDA < 0 { // Bottom right
-			cellSpaceCell.Y -= 1
+			cell := pixel.V(float64(x), float64(y))
+			if win.JustPressed(pixelgl.MouseButtonLeft) {
+				pathFrom = cell
+			} else if win.JustPressed(pixelgl.MouseButtonRight) {
+				path = w.FindPath(pathFrom, cell)
+			}
 		}
 
-		// Check that the cell is within the board
-		if cellSpaceCell.X >= 0 && cellSpaceCell.X < worldSizeX { // Check x bounds
-			if cellSpaceCell.Y >= 0 && cellSpaceCell.Y < worldSizeY { // Check y bounds
-				tileSprites[selected].Draw(win, pixel.IM.Moved(
-					pointToScreenSpace(cellSpaceCell.X, cellSpaceCell.Y),
-				)) // Draw the highlighted sprite on the cell
-			}
+		// Draw the most recently found path, if any, as highlighted tiles,
+		// each at its column's own surface height
+		for _, cell := range path {
+			z := surfaceZ(int(cell.X), int(cell.Y))
+			tileSprites[selected].Draw(win, pixel.IM.Moved(
+				pointToScreenSpace(cell.X, cell.Y, float64(z)),
+			).Chained(camMatrix))
 		}
 
 		win.Update() // Update the window
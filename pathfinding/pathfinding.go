@@ -0,0 +1,67 @@
+// Package pathfinding provides an A* path graph over a grid of tiles, built
+// on top of github.com/beefsack/go-astar.
+package pathfinding
+
+import (
+	"math"
+
+	astar "github.com/beefsack/go-astar"
+)
+
+// PathTile is a single node in the path graph: a grid cell plus links to its
+// up-to-8 neighbors. World.BuildPathGraph wires these up from the tile
+// grid; FindPath then runs A* across them.
+type PathTile struct {
+	X, Y int
+
+	// Walkable marks whether this tile can be entered at all.
+	Walkable bool
+
+	// Cost is a terrain cost multiplier applied to moves into this tile
+	// (1.0 for ordinary ground, higher for rough terrain such as dirt).
+	Cost float64
+
+	Up, Down, Left, Right                *PathTile
+	UpLeft, UpRight, DownLeft, DownRight *PathTile
+}
+
+// neighbors lists t's 8 neighbor slots in a fixed order.
+func (t *PathTile) neighbors() [8]*PathTile {
+	return [8]*PathTile{
+		t.Up, t.Down, t.Left, t.Right,
+		t.UpLeft, t.UpRight, t.DownLeft, t.DownRight,
+	}
+}
+
+// PathNeighbors returns t's walkable neighbors, satisfying astar.Pather.
+func (t *PathTile) PathNeighbors() []astar.Pather {
+	neighbors := t.neighbors()
+	out := make([]astar.Pather, 0, len(neighbors))
+	for _, n := range neighbors {
+		if n != nil && n.Walkable {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// PathNeighborCost returns the cost of moving from t into the neighbor to:
+// 1.0 for an orthogonal move or sqrt(2) for a diagonal one, scaled by the
+// destination tile's terrain cost multiplier.
+func (t *PathTile) PathNeighborCost(to astar.Pather) float64 {
+	neighbor := to.(*PathTile)
+	cost := 1.0
+	if neighbor.X != t.X && neighbor.Y != t.Y {
+		cost = math.Sqrt2
+	}
+	return cost * neighbor.Cost
+}
+
+// PathEstimatedCost estimates the remaining cost from t to to using octile
+// distance, the admissible heuristic for a grid with 8-directional movement.
+func (t *PathTile) PathEstimatedCost(to astar.Pather) float64 {
+	neighbor := to.(*PathTile)
+	dx := math.Abs(float64(t.X - neighbor.X))
+	dy := math.Abs(float64(t.Y - neighbor.Y))
+	return (dx + dy) + (math.Sqrt2-2)*math.Min(dx, dy)
+}
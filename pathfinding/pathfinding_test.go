@@ -0,0 +1,98 @@
+package pathfinding
+
+import (
+	"math"
+	"testing"
+)
+
+// grid3x3 returns a fully-wired 3x3 grid of walkable, cost-1 tiles centered
+// on (1, 1), so tests can exercise a tile with all 8 neighbors present.
+func grid3x3() map[[2]int]*PathTile {
+	tiles := make(map[[2]int]*PathTile, 9)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			tiles[[2]int{x, y}] = &PathTile{X: x, Y: y, Walkable: true, Cost: 1}
+		}
+	}
+	at := func(x, y int) *PathTile {
+		if x < 0 || x >= 3 || y < 0 || y >= 3 {
+			return nil
+		}
+		return tiles[[2]int{x, y}]
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			t := tiles[[2]int{x, y}]
+			t.Left, t.Right = at(x-1, y), at(x+1, y)
+			t.Down, t.Up = at(x, y-1), at(x, y+1)
+			t.DownLeft, t.DownRight = at(x-1, y-1), at(x+1, y-1)
+			t.UpLeft, t.UpRight = at(x-1, y+1), at(x+1, y+1)
+		}
+	}
+	return tiles
+}
+
+func TestPathNeighborsFiltersUnwalkableAndNil(t *testing.T) {
+	tiles := grid3x3()
+	center := tiles[[2]int{1, 1}]
+	center.Left.Walkable = false // one of the 8 in-bounds neighbors, now blocked
+
+	corner := tiles[[2]int{0, 0}] // only 3 of 8 neighbor slots are in-bounds
+
+	if got, want := len(center.PathNeighbors()), 7; got != want {
+		t.Errorf("center.PathNeighbors() has %d entries, want %d", got, want)
+	}
+	if got, want := len(corner.PathNeighbors()), 3; got != want {
+		t.Errorf("corner.PathNeighbors() has %d entries, want %d", got, want)
+	}
+	for _, n := range corner.PathNeighbors() {
+		if n == nil {
+			t.Errorf("corner.PathNeighbors() contains a nil neighbor")
+		}
+	}
+}
+
+func TestPathNeighborCost(t *testing.T) {
+	tiles := grid3x3()
+	center := tiles[[2]int{1, 1}]
+	center.Right.Cost = 2
+
+	tests := []struct {
+		name string
+		to   *PathTile
+		want float64
+	}{
+		{"orthogonal", tiles[[2]int{1, 2}], 1},
+		{"diagonal", tiles[[2]int{2, 2}], math.Sqrt2},
+		{"orthogonal scaled by destination cost", tiles[[2]int{2, 1}], 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := center.PathNeighborCost(tt.to); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PathNeighborCost(%v) = %v, want %v", tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathEstimatedCost(t *testing.T) {
+	a := &PathTile{X: 0, Y: 0}
+
+	tests := []struct {
+		name string
+		to   *PathTile
+		want float64
+	}{
+		{"same tile", &PathTile{X: 0, Y: 0}, 0},
+		{"orthogonal", &PathTile{X: 3, Y: 0}, 3},
+		{"pure diagonal", &PathTile{X: 3, Y: 3}, 3 * math.Sqrt2},
+		{"mixed", &PathTile{X: 4, Y: 1}, 1*math.Sqrt2 + 3}, // 1 diagonal step + 3 orthogonal
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.PathEstimatedCost(tt.to); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PathEstimatedCost(%v) = %v, want %v", tt.to, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel"
+)
+
+// tmxMap mirrors the subset of Tiled's TMX schema this engine consumes: an
+// isometric map made of one or more CSV layers backed by external TSX
+// tilesets.
+type tmxMap struct {
+	XMLName    xml.Name     `xml:"map"`
+	TileWidth  int          `xml:"tilewidth,attr"`
+	TileHeight int          `xml:"tileheight,attr"`
+	TileSets   []tmxTileset `xml:"tileset"`
+	Layers     []tmxLayer   `xml:"layer"`
+}
+
+type tmxTileset struct {
+	FirstGID uint32 `xml:"firstgid,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+type tmxLayer struct {
+	Name   string  `xml:"name,attr"`
+	Width  int     `xml:"width,attr"`
+	Height int     `xml:"height,attr"`
+	Data   tmxData `xml:"data"`
+}
+
+type tmxData struct {
+	Encoding string `xml:"encoding,attr"`
+	CSV      string `xml:",chardata"`
+}
+
+// tsxTileset mirrors the subset of Tiled's TSX schema referenced by a TMX
+// tileset element.
+type tsxTileset struct {
+	XMLName    xml.Name `xml:"tileset"`
+	TileWidth  int      `xml:"tilewidth,attr"`
+	TileHeight int      `xml:"tileheight,attr"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+		Width  int    `xml:"width,attr"`
+		Height int    `xml:"height,attr"`
+	} `xml:"image"`
+	Tiles []tsxTile `xml:"tile"`
+}
+
+// tsxTile holds the per-tile <properties> Tiled attaches to individual tiles
+// within a tileset, addressed by the tile's id (GID - FirstGID).
+type tsxTile struct {
+	ID         int           `xml:"id,attr"`
+	Properties []tsxProperty `xml:"properties>property"`
+}
+
+type tsxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Tileset holds the sprites cut from a TSX tileset image, indexed by
+// GID - FirstGID, and the GID at which the tileset begins. Walkable mirrors
+// TileImages' indexing: it's true unless the tile's TSX properties set
+// "walkable" to "false", letting a Tiled-authored map mark its own
+// obstacles independent of this engine's built-in tileType enum.
+type Tileset struct {
+	FirstGID   uint32
+	TileImages []*pixel.Sprite
+	Walkable   []bool
+}
+
+// loadTileset parses the TSX file referenced by a TMX tileset element and
+// slices its image into per-tile sprites, top row first to match Tiled's
+// GID ordering (GID 1 is the top-left tile of the image). Picture space has
+// y pointing up, so the top row is the one with the highest row index.
+func loadTileset(dir string, ts tmxTileset) (*Tileset, error) {
+	f, err := os.Open(filepath.Join(dir, ts.Source))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tsx tsxTileset
+	if err := xml.NewDecoder(f).Decode(&tsx); err != nil {
+		return nil, err
+	}
+
+	pic, err := loadPicture(filepath.Join(dir, tsx.Image.Source))
+	if err != nil {
+		return nil, err
+	}
+
+	cols := tsx.Image.Width / tsx.TileWidth
+	rows := tsx.Image.Height / tsx.TileHeight
+	images := make([]*pixel.Sprite, 0, cols*rows)
+	walkable := make([]bool, 0, cols*rows)
+	for row := rows - 1; row >= 0; row-- {
+		for col := 0; col < cols; col++ {
+			x := float64(col * tsx.TileWidth)
+			y := float64(row * tsx.TileHeight)
+			images = append(images, pixel.NewSprite(pic, pixel.R(
+				x, y, x+float64(tsx.TileWidth), y+float64(tsx.TileHeight),
+			)))
+			walkable = append(walkable, true)
+		}
+	}
+
+	for _, tile := range tsx.Tiles {
+		if tile.ID < 0 || tile.ID >= len(walkable) {
+			continue
+		}
+		for _, p := range tile.Properties {
+			if p.Name == "walkable" {
+				walkable[tile.ID] = p.Value != "false"
+			}
+		}
+	}
+
+	return &Tileset{FirstGID: ts.FirstGID, TileImages: images, Walkable: walkable}, nil
+}
+
+// registerTileset appends a tileset's sprites and per-tile walkability to
+// the runtime tables and returns the tileType at which its first tile
+// (GID == FirstGID) now lives.
+func registerTileset(ts *Tileset) tileType {
+	offset := tileType(len(tileSprites))
+	tileSprites = append(tileSprites, ts.TileImages...)
+	tileWalkable = append(tileWalkable, ts.Walkable...)
+	return offset
+}
+
+// parseCSVLayer decodes a TMX <data encoding="csv"> layer into a
+// layer.Height x layer.Width grid of raw GIDs, using the layer's own
+// declared dimensions as the row stride rather than assuming any fixed
+// world size.
+func parseCSVLayer(layer tmxLayer) ([][]uint32, error) {
+	fields := strings.Split(strings.TrimSpace(layer.Data.CSV), ",")
+	if len(fields) != layer.Width*layer.Height {
+		return nil, fmt.Errorf("layer %q: got %d cells, want %dx%d = %d",
+			layer.Name, len(fields), layer.Width, layer.Height, layer.Width*layer.Height)
+	}
+
+	grid := make([][]uint32, layer.Height)
+	for y := range grid {
+		grid[y] = make([]uint32, layer.Width)
+	}
+	for i, field := range fields {
+		v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: parsing gid %q: %w", layer.Name, field, err)
+		}
+		grid[i/layer.Width][i%layer.Width] = uint32(v)
+	}
+	return grid, nil
+}
+
+// LoadTMX parses a Tiled isometric TMX map at path and replaces world with
+// its layers, one z-level per <layer> in document order. Each tileset
+// referenced by the map is loaded and appended to the runtime sprite table
+// so its tiles can be drawn by the regular render loop in run().
+func LoadTMX(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	var m tmxMap
+	err = xml.NewDecoder(f).Decode(&m)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+
+	type registeredTileset struct {
+		firstGID uint32
+		offset   tileType
+	}
+	registered := make([]registeredTileset, 0, len(m.TileSets))
+	for _, ts := range m.TileSets {
+		tileset, err := loadTileset(dir, ts)
+		if err != nil {
+			return fmt.Errorf("loading tileset %q: %w", ts.Source, err)
+		}
+		registered = append(registered, registeredTileset{
+			firstGID: tileset.FirstGID,
+			offset:   registerTileset(tileset),
+		})
+	}
+
+	// gidToTile resolves a GID to its tileType in the runtime sprite
+	// table, using the tileset with the largest FirstGID <= gid. A map
+	// with no <tileset> elements (as written by SaveTMX) has no GIDs to
+	// resolve at all, so its values are taken as literal tileTypes.
+	gidToTile := func(gid uint32) tileType {
+		if gid == 0 {
+			return blank
+		}
+		if len(registered) == 0 {
+			return tileType(gid)
+		}
+		best := registered[0]
+		for _, ts := range registered {
+			if ts.firstGID <= gid && ts.firstGID >= best.firstGID {
+				best = ts
+			}
+		}
+		return best.offset + tileType(gid-best.firstGID)
+	}
+
+	if len(m.Layers) == 0 {
+		return fmt.Errorf("%s: map has no layers", path)
+	}
+	for _, layer := range m.Layers {
+		if layer.Width != m.Layers[0].Width || layer.Height != m.Layers[0].Height {
+			return fmt.Errorf("layer %q is %dx%d, want %dx%d like layer %q",
+				layer.Name, layer.Width, layer.Height,
+				m.Layers[0].Width, m.Layers[0].Height, m.Layers[0].Name)
+		}
+	}
+
+	// Resize the world to match the map before populating it, so
+	// newLayer-sized structures (and everything downstream that reads
+	// worldSizeX/worldSizeY) line up with what's actually loaded.
+	worldSizeX, worldSizeY = m.Layers[0].Width, m.Layers[0].Height
+	environment = newLayer() // Old size/contents no longer apply
+
+	world = map[int][][]tileType{}
+	for z, layer := range m.Layers {
+		gids, err := parseCSVLayer(layer)
+		if err != nil {
+			return err
+		}
+		grid := newLayer()
+		for y := range gids {
+			for x, gid := range gids[y] {
+				grid[y][x] = gidToTile(gid)
+			}
+		}
+		world[z] = grid
+	}
+	worldHeight = len(m.Layers)
+
+	return nil
+}
+
+// SaveTMX writes the current world out as a Tiled-compatible TMX map at
+// path, so an external editor such as Tiled can be used to touch up maps
+// that originated in-engine. It emits no <tileset>, so its GIDs are raw
+// tileType values; LoadTMX recognizes a tileset-less map and treats GIDs
+// the same way, so SaveTMX and LoadTMX round-trip a world's tile values
+// (though not sprites from externally authored tilesets).
+func SaveTMX(path string) error {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&sb, "<map version=\"1.2\" orientation=\"isometric\" renderorder=\"right-down\" "+
+		"width=\"%d\" height=\"%d\" tilewidth=\"%d\" tileheight=\"%d\">\n",
+		worldSizeX, worldSizeY, int(tileSize.X), int(tileSize.Y))
+
+	for z := 0; z < worldHeight; z++ {
+		fmt.Fprintf(&sb, "  <layer id=\"%d\" name=\"layer%d\" width=\"%d\" height=\"%d\">\n",
+			z+1, z, worldSizeX, worldSizeY)
+		sb.WriteString("    <data encoding=\"csv\">\n")
+		grid := world[z]
+		rows := make([]string, worldSizeY)
+		for y := 0; y < worldSizeY; y++ {
+			cells := make([]string, worldSizeX)
+			for x := 0; x < worldSizeX; x++ {
+				cells[x] = strconv.Itoa(int(grid[y][x]))
+			}
+			rows[y] = strings.Join(cells, ",")
+		}
+		sb.WriteString(strings.Join(rows, ",\n"))
+		sb.WriteString("\n    </data>\n  </layer>\n")
+	}
+	sb.WriteString("</map>\n")
+
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
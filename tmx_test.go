@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadTMXRoundTrip(t *testing.T) {
+	origSizeX, origSizeY := worldSizeX, worldSizeY
+	origWorld, origHeight, origEnv := world, worldHeight, environment
+	defer func() {
+		worldSizeX, worldSizeY = origSizeX, origSizeY
+		world, worldHeight, environment = origWorld, origHeight, origEnv
+	}()
+
+	worldSizeX, worldSizeY = 3, 2
+	world = map[int][][]tileType{}
+	growWorld(1)
+	world[0][0] = []tileType{grass, stone, grass}
+	world[0][1] = []tileType{grass, grass, stone}
+	want := world[0]
+
+	path := filepath.Join(t.TempDir(), "map.tmx")
+	if err := SaveTMX(path); err != nil {
+		t.Fatalf("SaveTMX: %v", err)
+	}
+
+	// Reset the world to a different size so a correct LoadTMX has to
+	// resize it back to match the saved map rather than coincidentally
+	// matching leftover state.
+	worldSizeX, worldSizeY = 10, 10
+	world = map[int][][]tileType{}
+	worldHeight = 0
+
+	if err := LoadTMX(path); err != nil {
+		t.Fatalf("LoadTMX: %v", err)
+	}
+
+	if worldSizeX != 3 || worldSizeY != 2 {
+		t.Fatalf("worldSizeX, worldSizeY = %d, %d, want 3, 2", worldSizeX, worldSizeY)
+	}
+	if worldHeight != 1 {
+		t.Fatalf("worldHeight = %d, want 1", worldHeight)
+	}
+	for y := range want {
+		for x := range want[y] {
+			if got := world[0][y][x]; got != want[y][x] {
+				t.Errorf("world[0][%d][%d] = %v, want %v", y, x, got, want[y][x])
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	astar "github.com/beefsack/go-astar"
+	"github.com/faiface/pixel"
+	"github.com/xoreo/isometric-engine/pathfinding"
+)
+
+// World is a thin handle for operations that act on the package-level tile
+// grid; pathfinding is built this way (rather than as free functions) so it
+// reads the same as the Tileset/World-shaped APIs the engine is growing
+// into.
+type World struct{}
+
+// pathGraph holds the most recent graph built by BuildPathGraph, keyed by
+// cell coordinates.
+var pathGraph map[[2]int]*pathfinding.PathTile
+
+// terrainCost returns the pathfinding cost multiplier for walking onto a
+// tile of type t: grass is the baseline, rougher terrain like dirt costs
+// more to cross so FindPath prefers grass where it can.
+func terrainCost(t tileType) float64 {
+	switch t {
+	case dirt:
+		return 1.5
+	default:
+		return 1
+	}
+}
+
+// surfaceTile returns the topmost non-blank tile at (x, y) across all
+// z-levels, which is the one pathfinding treats as that column's ground.
+func surfaceTile(x, y int) tileType {
+	for z := worldHeight - 1; z >= 0; z-- {
+		if t := world[z][y][x]; t != blank {
+			return t
+		}
+	}
+	return blank
+}
+
+// walkable reports whether t can be entered, consulting the runtime
+// tileWalkable table (see its doc comment) rather than comparing against
+// any specific built-in tileType, so tiles registered from a loaded
+// tileset — whose values live past the built-in enum entirely — are
+// recognized too.
+func walkable(t tileType) bool {
+	if int(t) >= len(tileWalkable) {
+		return true
+	}
+	return tileWalkable[t]
+}
+
+// BuildPathGraph walks the world's surface, marking unwalkable columns (per
+// tileWalkable) as such, and wires every tile to its up-to-8 neighbors so
+// FindPath can run A* over the result.
+func (World) BuildPathGraph() {
+	tiles := make(map[[2]int]*pathfinding.PathTile, worldSizeX*worldSizeY)
+	for y := 0; y < worldSizeY; y++ {
+		for x := 0; x < worldSizeX; x++ {
+			t := surfaceTile(x, y)
+			tiles[[2]int{x, y}] = &pathfinding.PathTile{
+				X:        x,
+				Y:        y,
+				Walkable: walkable(t),
+				Cost:     terrainCost(t),
+			}
+		}
+	}
+
+	at := func(x, y int) *pathfinding.PathTile {
+		if x < 0 || x >= worldSizeX || y < 0 || y >= worldSizeY {
+			return nil
+		}
+		return tiles[[2]int{x, y}]
+	}
+	for y := 0; y < worldSizeY; y++ {
+		for x := 0; x < worldSizeX; x++ {
+			tile := at(x, y)
+			tile.Left = at(x-1, y)
+			tile.Right = at(x+1, y)
+			tile.Down = at(x, y-1)
+			tile.Up = at(x, y+1)
+			tile.DownLeft = at(x-1, y-1)
+			tile.DownRight = at(x+1, y-1)
+			tile.UpLeft = at(x-1, y+1)
+			tile.UpRight = at(x+1, y+1)
+		}
+	}
+
+	pathGraph = tiles
+}
+
+// FindPath runs A* between the tiles at from and to (in cell space) over
+// the graph built by BuildPathGraph, and returns the cell-space waypoints
+// of the path from from to to inclusive, or nil if no path exists.
+func (World) FindPath(from, to pixel.Vec) []pixel.Vec {
+	if pathGraph == nil {
+		return nil
+	}
+
+	start := pathGraph[[2]int{int(from.X), int(from.Y)}]
+	goal := pathGraph[[2]int{int(to.X), int(to.Y)}]
+	if start == nil || goal == nil {
+		return nil
+	}
+
+	path, _, found := astar.Path(start, goal)
+	if !found {
+		return nil
+	}
+
+	cells := make([]pixel.Vec, len(path))
+	for i, p := range path {
+		tile := p.(*pathfinding.PathTile)
+		cells[len(path)-1-i] = pixel.V(float64(tile.X), float64(tile.Y))
+	}
+	return cells
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/faiface/pixel"
+)
+
+func TestBuildPathGraphAndFindPath(t *testing.T) {
+	origSizeX, origSizeY := worldSizeX, worldSizeY
+	origWorld, origHeight := world, worldHeight
+	origGraph := pathGraph
+	defer func() {
+		worldSizeX, worldSizeY = origSizeX, origSizeY
+		world, worldHeight = origWorld, origHeight
+		pathGraph = origGraph
+	}()
+
+	worldSizeX, worldSizeY = 3, 3
+	world = map[int][][]tileType{}
+	worldHeight = 0
+	growWorld(1)
+	for y := 0; y < worldSizeY; y++ {
+		for x := 0; x < worldSizeX; x++ {
+			world[0][y][x] = grass
+		}
+	}
+	// Wall off most of the middle column so a path from the left edge to
+	// the right edge has to detour through the one open row.
+	world[0][0][1] = stone
+	world[0][1][1] = stone
+
+	w := World{}
+	w.BuildPathGraph()
+
+	path := w.FindPath(pixel.V(0, 1), pixel.V(2, 1))
+	if path == nil {
+		t.Fatal("FindPath found no route around the wall, want a detour")
+	}
+	for _, cell := range path {
+		if int(cell.X) == 1 && int(cell.Y) != 2 {
+			t.Errorf("path %v passes through a blocked cell %v", path, cell)
+		}
+	}
+
+	// Close the one remaining gap in the wall and expect no path at all.
+	world[0][2][1] = stone
+	w.BuildPathGraph()
+	if got := w.FindPath(pixel.V(0, 1), pixel.V(2, 1)); got != nil {
+		t.Fatalf("FindPath = %v, want nil once the wall has no gap", got)
+	}
+}